@@ -0,0 +1,65 @@
+package headers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ServerKindMismatchError is returned in the response body when a request's
+// HeaderKeyExpectedServerKind does not match the server's configured kind.
+type ServerKindMismatchError struct {
+	Code     string `json:"code"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// ExpectedServerKindMiddleware rejects requests whose HeaderKeyExpectedServerKind
+// header disagrees with the server's configured serverKind, returning a
+// structured 409 error instead of silently processing a request meant for a
+// different kind of server (e.g. an SDK registering against Cloud when it
+// expects the Dev Server, or vice versa).
+//
+// Requests without the header are allowed through unchecked, since older SDKs
+// may not send it.
+func ExpectedServerKindMiddleware(serverKind string) func(http.Handler) http.Handler {
+	if serverKind == "" {
+		panic("server kind must be set")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			expected := r.Header.Get(HeaderKeyExpectedServerKind)
+			if expected == "" || expected == serverKind {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(ServerKindMismatchError{
+				Code:     "server_kind_mismatch",
+				Expected: expected,
+				Actual:   serverKind,
+			})
+		})
+	}
+}
+
+// StampExpectedServerKind sets the HeaderKeyExpectedServerKind header on an
+// outbound registration request so the receiving server can validate that the
+// entire registration flow is targeting the same kind of server.
+func StampExpectedServerKind(req *http.Request, expected string) {
+	req.Header.Set(HeaderKeyExpectedServerKind, expected)
+}
+
+// RegistrationMiddleware combines StaticHeadersMiddleware and
+// ExpectedServerKindMiddleware, which together let registration flows fail
+// fast and loudly instead of silently talking to the wrong server.
+func RegistrationMiddleware(serverKind string) func(http.Handler) http.Handler {
+	static := StaticHeadersMiddleware(serverKind)
+	expected := ExpectedServerKindMiddleware(serverKind)
+
+	return func(next http.Handler) http.Handler {
+		return static(expected(next))
+	}
+}
@@ -0,0 +1,66 @@
+package headers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestExpectedServerKindMiddleware_Match(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(HeaderKeyExpectedServerKind, ServerKindCloud)
+
+	rec := httptest.NewRecorder()
+	ExpectedServerKindMiddleware(ServerKindCloud)(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 when kinds match, got %d", rec.Code)
+	}
+}
+
+func TestExpectedServerKindMiddleware_Missing(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	rec := httptest.NewRecorder()
+	ExpectedServerKindMiddleware(ServerKindCloud)(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected requests without the header to pass through, got %d", rec.Code)
+	}
+}
+
+func TestExpectedServerKindMiddleware_Mismatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(HeaderKeyExpectedServerKind, ServerKindDev)
+
+	rec := httptest.NewRecorder()
+	ExpectedServerKindMiddleware(ServerKindCloud)(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 on a kind mismatch, got %d", rec.Code)
+	}
+
+	var body ServerKindMismatchError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON body, got decode error: %v", err)
+	}
+
+	want := ServerKindMismatchError{
+		Code:     "server_kind_mismatch",
+		Expected: ServerKindDev,
+		Actual:   ServerKindCloud,
+	}
+	if body != want {
+		t.Fatalf("expected body %+v, got %+v", want, body)
+	}
+}
+
+func TestStampExpectedServerKind(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	StampExpectedServerKind(req, ServerKindDev)
+
+	if got := req.Header.Get(HeaderKeyExpectedServerKind); got != ServerKindDev {
+		t.Fatalf("expected header to be set to %q, got %q", ServerKindDev, got)
+	}
+}
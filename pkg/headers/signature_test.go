@@ -0,0 +1,149 @@
+package headers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSignedRequest(t *testing.T, key []byte, body string, ts time.Time) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	sig := computeSignature(key, []byte(body), ts)
+	req.Header.Set(HeaderKeySignature, fmt.Sprintf("t=%d&s=%s", ts.UnixMilli(), sig))
+	return req
+}
+
+func passthrough() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	})
+}
+
+func TestSignatureVerificationMiddleware_Valid(t *testing.T) {
+	key := []byte("secret")
+	req := newSignedRequest(t, key, `{"hello":"world"}`, time.Now())
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{key})(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != `{"hello":"world"}` {
+		t.Fatalf("expected downstream handler to read the buffered body, got %q", rec.Body.String())
+	}
+}
+
+func TestSignatureVerificationMiddleware_StaleTimestamp(t *testing.T) {
+	key := []byte("secret")
+	req := newSignedRequest(t, key, "body", time.Now().Add(-10*time.Minute))
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{key})(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a stale timestamp, got %d", rec.Code)
+	}
+}
+
+func TestSignatureVerificationMiddleware_CustomSkew(t *testing.T) {
+	key := []byte("secret")
+	req := newSignedRequest(t, key, "body", time.Now().Add(-10*time.Minute))
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{key}, WithSignatureSkew(15*time.Minute))(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 within a widened skew window, got %d", rec.Code)
+	}
+}
+
+func TestSignatureVerificationMiddleware_KeyRotation(t *testing.T) {
+	oldKey := []byte("old-secret")
+	newKey := []byte("new-secret")
+	req := newSignedRequest(t, oldKey, "body", time.Now())
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{newKey, oldKey})(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 when the body validates against any accepted key, got %d", rec.Code)
+	}
+}
+
+func TestSignatureVerificationMiddleware_WrongKey(t *testing.T) {
+	req := newSignedRequest(t, []byte("signed-with-this"), "body", time.Now())
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{[]byte("not-this")})(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a signature that matches no accepted key, got %d", rec.Code)
+	}
+}
+
+func TestSignatureVerificationMiddleware_MissingHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("body"))
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{[]byte("secret")})(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for a missing signature header, got %d", rec.Code)
+	}
+}
+
+func TestSignatureVerificationMiddleware_MalformedHeader(t *testing.T) {
+	for _, header := range []string{"", "t=notanumber&s=abcd", "garbage", "t=123"} {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("body"))
+		req.Header.Set(HeaderKeySignature, header)
+
+		rec := httptest.NewRecorder()
+		SignatureVerificationMiddleware([][]byte{[]byte("secret")})(passthrough()).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for malformed header %q, got %d", header, rec.Code)
+		}
+	}
+}
+
+func TestSignatureVerificationMiddleware_Bypass(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("body"))
+
+	rec := httptest.NewRecorder()
+	mw := SignatureVerificationMiddleware([][]byte{[]byte("secret")}, WithSignatureBypass(func(*http.Request) bool {
+		return true
+	}))
+	mw(passthrough()).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected bypass predicate to skip verification, got %d", rec.Code)
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	key := []byte("secret")
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("payload"))
+
+	if err := SignRequest(req, key); err != nil {
+		t.Fatalf("SignRequest: %v", err)
+	}
+
+	if req.Header.Get(HeaderKeySignature) == "" {
+		t.Fatalf("expected SignRequest to set %s", HeaderKeySignature)
+	}
+
+	rec := httptest.NewRecorder()
+	SignatureVerificationMiddleware([][]byte{key})(passthrough()).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected a request signed by SignRequest to verify, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
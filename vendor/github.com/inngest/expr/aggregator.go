@@ -0,0 +1,17 @@
+package expr
+
+// defaultEngines returns the set of MatchingEngine implementations the
+// aggregator indexes expressions into.  Add is expected to offer each
+// ExpressionPart to every engine in this list; an engine that doesn't
+// support the predicate's operator returns an error from Add, letting the
+// aggregator route that part elsewhere (or fall back to linear evaluation)
+// without losing the other engines' indexing for the same expression.  This
+// is what keeps mixed expressions, such as `==` and `startsWith` on the same
+// variable, indexed rather than falling back to linear evaluation entirely.
+func defaultEngines(concurrency int64) []MatchingEngine {
+	return []MatchingEngine{
+		newStringEqualityMatcher(concurrency),
+		newStringAffixMatcher(concurrency),
+		newNumericRangeMatcher(concurrency),
+	}
+}
@@ -0,0 +1,342 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/ohler55/ojg/jp"
+)
+
+// EngineTypeNumericRange matches <, <=, >, and >= predicates via sorted,
+// per-variable threshold slices rather than falling back to full CEL
+// evaluation.
+const EngineTypeNumericRange EngineType = 11
+
+func newNumericRangeMatcher(concurrency int64) MatchingEngine {
+	return &numericRange{
+		lock:        &sync.RWMutex{},
+		vars:        map[string]struct{}{},
+		gt:          map[string][]numericEntry{},
+		gte:         map[string][]numericEntry{},
+		lt:          map[string][]numericEntry{},
+		lte:         map[string][]numericEntry{},
+		gtTime:      map[string][]timeEntry{},
+		gteTime:     map[string][]timeEntry{},
+		ltTime:      map[string][]timeEntry{},
+		lteTime:     map[string][]timeEntry{},
+		concurrency: concurrency,
+	}
+}
+
+type numericEntry struct {
+	threshold float64
+	part      *StoredExpressionPart
+}
+
+// timeEntry stores time.Time thresholds as exact unix nanoseconds.  Unlike
+// numericEntry, this is never represented as a float64: nanosecond-precision
+// unix timestamps (~1.7e18 today) are well beyond float64's 2^53
+// exact-integer range, so a float64 cast loses enough precision to compare
+// two distinct nearby timestamps as equal, or in the wrong order.
+type timeEntry struct {
+	threshold int64
+	part      *StoredExpressionPart
+}
+
+// numericRange indexes <, <=, >, and >= predicates by keeping a sorted slice
+// of thresholds per variable and per operator.  Matching a value against n
+// stored thresholds is a binary search to find the boundary plus a linear
+// scan of the k matching entries, rather than evaluating all n expressions.
+//
+// Numeric (int/float) literals and time.Time literals are kept in separate
+// slices so that time.Time comparisons stay in the exact int64-nanosecond
+// domain rather than being downcast to float64.
+type numericRange struct {
+	lock *sync.RWMutex
+
+	vars map[string]struct{}
+
+	// gt and gte store ">" and ">=" predicates, sorted ascending by
+	// threshold.  A value matches a prefix of the slice (every threshold
+	// strictly less than, or less than or equal to, the value).
+	gt  map[string][]numericEntry
+	gte map[string][]numericEntry
+	// lt and lte store "<" and "<=" predicates, sorted ascending by
+	// threshold.  A value matches a suffix of the slice.
+	lt  map[string][]numericEntry
+	lte map[string][]numericEntry
+
+	// gtTime, gteTime, ltTime, and lteTime mirror gt/gte/lt/lte, but for
+	// time.Time literals, keyed and compared as exact int64 unix nanos.
+	gtTime  map[string][]timeEntry
+	gteTime map[string][]timeEntry
+	ltTime  map[string][]timeEntry
+	lteTime map[string][]timeEntry
+
+	concurrency int64
+}
+
+func (n *numericRange) Type() EngineType {
+	return EngineTypeNumericRange
+}
+
+func (n *numericRange) Add(ctx context.Context, p ExpressionPart) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.vars[p.Predicate.Ident] = struct{}{}
+	stored := p.ToStored()
+
+	if ts, ok := p.Predicate.Literal.(time.Time); ok {
+		nanos := ts.UnixNano()
+		switch p.Predicate.Operator {
+		case operators.Greater:
+			n.gtTime[p.Predicate.Ident] = insertSortedTime(n.gtTime[p.Predicate.Ident], timeEntry{nanos, stored})
+		case operators.GreaterEquals:
+			n.gteTime[p.Predicate.Ident] = insertSortedTime(n.gteTime[p.Predicate.Ident], timeEntry{nanos, stored})
+		case operators.Less:
+			n.ltTime[p.Predicate.Ident] = insertSortedTime(n.ltTime[p.Predicate.Ident], timeEntry{nanos, stored})
+		case operators.LessEquals:
+			n.lteTime[p.Predicate.Ident] = insertSortedTime(n.lteTime[p.Predicate.Ident], timeEntry{nanos, stored})
+		default:
+			return fmt.Errorf("numericRange engines only support <, <=, >, and >=")
+		}
+		return nil
+	}
+
+	threshold, ok := asFloat64(p.Predicate.Literal)
+	if !ok {
+		return fmt.Errorf("numericRange engines require a numeric or time.Time literal, got %T", p.Predicate.Literal)
+	}
+
+	switch p.Predicate.Operator {
+	case operators.Greater:
+		n.gt[p.Predicate.Ident] = insertSorted(n.gt[p.Predicate.Ident], numericEntry{threshold, stored})
+	case operators.GreaterEquals:
+		n.gte[p.Predicate.Ident] = insertSorted(n.gte[p.Predicate.Ident], numericEntry{threshold, stored})
+	case operators.Less:
+		n.lt[p.Predicate.Ident] = insertSorted(n.lt[p.Predicate.Ident], numericEntry{threshold, stored})
+	case operators.LessEquals:
+		n.lte[p.Predicate.Ident] = insertSorted(n.lte[p.Predicate.Ident], numericEntry{threshold, stored})
+	default:
+		return fmt.Errorf("numericRange engines only support <, <=, >, and >=")
+	}
+
+	return nil
+}
+
+func (n *numericRange) Remove(ctx context.Context, p ExpressionPart) error {
+	stored := p.ToStored()
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	var ok bool
+
+	if ts, isTime := p.Predicate.Literal.(time.Time); isTime {
+		nanos := ts.UnixNano()
+		switch p.Predicate.Operator {
+		case operators.Greater:
+			n.gtTime[p.Predicate.Ident], ok = removeSortedTime(n.gtTime[p.Predicate.Ident], nanos, stored)
+		case operators.GreaterEquals:
+			n.gteTime[p.Predicate.Ident], ok = removeSortedTime(n.gteTime[p.Predicate.Ident], nanos, stored)
+		case operators.Less:
+			n.ltTime[p.Predicate.Ident], ok = removeSortedTime(n.ltTime[p.Predicate.Ident], nanos, stored)
+		case operators.LessEquals:
+			n.lteTime[p.Predicate.Ident], ok = removeSortedTime(n.lteTime[p.Predicate.Ident], nanos, stored)
+		default:
+			return fmt.Errorf("numericRange engines only support <, <=, >, and >=")
+		}
+
+		if !ok {
+			return ErrExpressionPartNotFound
+		}
+		return nil
+	}
+
+	threshold, tok := asFloat64(p.Predicate.Literal)
+	if !tok {
+		return fmt.Errorf("numericRange engines require a numeric or time.Time literal, got %T", p.Predicate.Literal)
+	}
+
+	switch p.Predicate.Operator {
+	case operators.Greater:
+		n.gt[p.Predicate.Ident], ok = removeSorted(n.gt[p.Predicate.Ident], threshold, stored)
+	case operators.GreaterEquals:
+		n.gte[p.Predicate.Ident], ok = removeSorted(n.gte[p.Predicate.Ident], threshold, stored)
+	case operators.Less:
+		n.lt[p.Predicate.Ident], ok = removeSorted(n.lt[p.Predicate.Ident], threshold, stored)
+	case operators.LessEquals:
+		n.lte[p.Predicate.Ident], ok = removeSorted(n.lte[p.Predicate.Ident], threshold, stored)
+	default:
+		return fmt.Errorf("numericRange engines only support <, <=, >, and >=")
+	}
+
+	if !ok {
+		return ErrExpressionPartNotFound
+	}
+	return nil
+}
+
+func (n *numericRange) Match(ctx context.Context, input map[string]any, result *MatchResult) error {
+	pool := newErrPool(errPoolOpts{concurrency: n.concurrency})
+	for item := range n.vars {
+		path := item
+		pool.Go(func() error {
+			x, err := jp.ParseString(path)
+			if err != nil {
+				return err
+			}
+
+			res := x.Get(input)
+			if len(res) == 0 {
+				return nil
+			}
+
+			n.Search(ctx, path, res[0], result)
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
+// Search returns all StoredExpressionParts whose <, <=, >, or >= predicate is
+// satisfied by input, restricted to thresholds indexed for this variable.  time.Time inputs
+// are compared exactly, in the int64-nanosecond domain, against time.Time
+// thresholds; everything else is compared as float64.
+func (n *numericRange) Search(ctx context.Context, variable string, input any, result *MatchResult) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if ts, ok := input.(time.Time); ok {
+		n.searchTime(variable, ts.UnixNano(), result)
+		return
+	}
+
+	value, ok := asFloat64(input)
+	if !ok {
+		// The input is missing or the wrong type at this path; there's
+		// nothing to range-match against.
+		return
+	}
+
+	// threshold < value
+	entries := n.gt[variable]
+	idx := sort.Search(len(entries), func(i int) bool { return entries[i].threshold >= value })
+	addEntries(entries[:idx], result)
+
+	// threshold <= value
+	entries = n.gte[variable]
+	idx = sort.Search(len(entries), func(i int) bool { return entries[i].threshold > value })
+	addEntries(entries[:idx], result)
+
+	// threshold > value
+	entries = n.lt[variable]
+	idx = sort.Search(len(entries), func(i int) bool { return entries[i].threshold > value })
+	addEntries(entries[idx:], result)
+
+	// threshold >= value
+	entries = n.lte[variable]
+	idx = sort.Search(len(entries), func(i int) bool { return entries[i].threshold >= value })
+	addEntries(entries[idx:], result)
+}
+
+func (n *numericRange) searchTime(variable string, value int64, result *MatchResult) {
+	// threshold < value
+	entries := n.gtTime[variable]
+	idx := sort.Search(len(entries), func(i int) bool { return entries[i].threshold >= value })
+	addTimeEntries(entries[:idx], result)
+
+	// threshold <= value
+	entries = n.gteTime[variable]
+	idx = sort.Search(len(entries), func(i int) bool { return entries[i].threshold > value })
+	addTimeEntries(entries[:idx], result)
+
+	// threshold > value
+	entries = n.ltTime[variable]
+	idx = sort.Search(len(entries), func(i int) bool { return entries[i].threshold > value })
+	addTimeEntries(entries[idx:], result)
+
+	// threshold >= value
+	entries = n.lteTime[variable]
+	idx = sort.Search(len(entries), func(i int) bool { return entries[i].threshold >= value })
+	addTimeEntries(entries[idx:], result)
+}
+
+func addEntries(entries []numericEntry, result *MatchResult) {
+	for _, e := range entries {
+		result.Add(e.part.EvaluableID, e.part.GroupID)
+	}
+}
+
+func addTimeEntries(entries []timeEntry, result *MatchResult) {
+	for _, e := range entries {
+		result.Add(e.part.EvaluableID, e.part.GroupID)
+	}
+}
+
+func insertSorted(entries []numericEntry, e numericEntry) []numericEntry {
+	idx := sort.Search(len(entries), func(i int) bool { return entries[i].threshold >= e.threshold })
+	entries = append(entries, numericEntry{})
+	copy(entries[idx+1:], entries[idx:])
+	entries[idx] = e
+	return entries
+}
+
+func removeSorted(entries []numericEntry, threshold float64, part *StoredExpressionPart) ([]numericEntry, bool) {
+	for i, e := range entries {
+		if e.threshold != threshold {
+			continue
+		}
+		if !part.EqualsStored(e.part) {
+			continue
+		}
+		return append(entries[:i], entries[i+1:]...), true
+	}
+	return entries, false
+}
+
+func insertSortedTime(entries []timeEntry, e timeEntry) []timeEntry {
+	idx := sort.Search(len(entries), func(i int) bool { return entries[i].threshold >= e.threshold })
+	entries = append(entries, timeEntry{})
+	copy(entries[idx+1:], entries[idx:])
+	entries[idx] = e
+	return entries
+}
+
+func removeSortedTime(entries []timeEntry, threshold int64, part *StoredExpressionPart) ([]timeEntry, bool) {
+	for i, e := range entries {
+		if e.threshold != threshold {
+			continue
+		}
+		if !part.EqualsStored(e.part) {
+			continue
+		}
+		return append(entries[:i], entries[i+1:]...), true
+	}
+	return entries, false
+}
+
+// asFloat64 converts supported numeric literal/input kinds to float64.
+// time.Time is handled separately, in the exact int64-nanosecond domain; see
+// timeEntry.
+func asFloat64(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
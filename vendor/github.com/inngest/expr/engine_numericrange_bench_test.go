@@ -0,0 +1,51 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/cel-go/common/operators"
+)
+
+// benchNumericRange populates a numericRange engine with n ">" predicates on
+// the same variable, spread evenly across a threshold range.
+func benchNumericRange(b *testing.B, n int) *numericRange {
+	b.Helper()
+
+	engine := newNumericRangeMatcher(1).(*numericRange)
+	ctx := context.Background()
+
+	for i := 0; i < n; i++ {
+		part := ExpressionPart{
+			Predicate: Predicate{
+				Ident:    "event.data.amount",
+				Operator: operators.Greater,
+				Literal:  float64(i),
+			},
+		}
+		if err := engine.Add(ctx, part); err != nil {
+			b.Fatalf("failed to add predicate: %v", err)
+		}
+	}
+
+	return engine
+}
+
+func BenchmarkNumericRangeMatch(b *testing.B) {
+	for _, n := range []int{100, 1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			engine := benchNumericRange(b, n)
+			input := map[string]any{"event": map[string]any{"data": map[string]any{"amount": float64(n / 2)}}}
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				result := &MatchResult{}
+				if err := engine.Match(ctx, input, result); err != nil {
+					b.Fatalf("match failed: %v", err)
+				}
+			}
+		})
+	}
+}
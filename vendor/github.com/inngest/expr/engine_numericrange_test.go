@@ -0,0 +1,239 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/cel-go/common/operators"
+)
+
+func numericPart(ident, operator string, literal any) ExpressionPart {
+	return ExpressionPart{
+		Predicate: Predicate{
+			Ident:    ident,
+			Operator: operator,
+			Literal:  literal,
+		},
+	}
+}
+
+func TestNumericRange_AddUnsupportedOperator(t *testing.T) {
+	n := newNumericRangeMatcher(1).(*numericRange)
+	err := n.Add(context.Background(), numericPart("a", operators.Equals, float64(1)))
+	if err == nil {
+		t.Fatalf("expected an error for an unsupported operator")
+	}
+}
+
+func TestNumericRange_AddNonNumericLiteral(t *testing.T) {
+	n := newNumericRangeMatcher(1).(*numericRange)
+	err := n.Add(context.Background(), numericPart("a", operators.Greater, "not-a-number"))
+	if err == nil {
+		t.Fatalf("expected an error for a non-numeric, non-time.Time literal")
+	}
+}
+
+func TestNumericRange_RemoveMissing(t *testing.T) {
+	n := newNumericRangeMatcher(1).(*numericRange)
+	err := n.Remove(context.Background(), numericPart("a", operators.Greater, float64(1)))
+	if err != ErrExpressionPartNotFound {
+		t.Fatalf("expected ErrExpressionPartNotFound, got %v", err)
+	}
+}
+
+func TestNumericRange_AddRemoveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+
+	part := numericPart("amount", operators.Greater, float64(100))
+	if err := n.Add(ctx, part); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if len(n.gt["amount"]) != 1 {
+		t.Fatalf("expected one entry after add, got %d", len(n.gt["amount"]))
+	}
+
+	if err := n.Remove(ctx, part); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if len(n.gt["amount"]) != 0 {
+		t.Fatalf("expected no entries after remove, got %d", len(n.gt["amount"]))
+	}
+}
+
+func TestNumericRange_GreaterThanBoundary(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+
+	for _, threshold := range []float64{10, 20, 30} {
+		if err := n.Add(ctx, numericPart("amount", operators.Greater, threshold)); err != nil {
+			t.Fatalf("add %v: %v", threshold, err)
+		}
+	}
+	entries := n.gt["amount"]
+
+	result := &MatchResult{}
+	n.Search(ctx, "amount", float64(20), result)
+
+	// The predicate is "amount > threshold", so it's satisfied when
+	// threshold < value. Only
+	// the threshold-10 entry is < 20; threshold 20 is not (20 > 20 is
+	// false), and neither is 30.
+	for _, e := range entries {
+		want := e.threshold < 20
+		if got := matched(result, e.part); got != want {
+			t.Fatalf("threshold %v: expected matched=%v, got %v", e.threshold, want, got)
+		}
+	}
+}
+
+func TestNumericRange_GreaterThanOrEqualBoundary(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+
+	for _, threshold := range []float64{10, 20, 30} {
+		if err := n.Add(ctx, numericPart("amount", operators.GreaterEquals, threshold)); err != nil {
+			t.Fatalf("add %v: %v", threshold, err)
+		}
+	}
+	entries := n.gte["amount"]
+
+	result := &MatchResult{}
+	n.Search(ctx, "amount", float64(20), result)
+
+	// "amount >= threshold" is satisfied when threshold <= value: thresholds
+	// 10 and 20 qualify, 30 does not.
+	for _, e := range entries {
+		want := e.threshold <= 20
+		if got := matched(result, e.part); got != want {
+			t.Fatalf("threshold %v: expected matched=%v, got %v", e.threshold, want, got)
+		}
+	}
+}
+
+func TestNumericRange_LessThanBoundary(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+
+	for _, threshold := range []float64{10, 20, 30} {
+		if err := n.Add(ctx, numericPart("amount", operators.Less, threshold)); err != nil {
+			t.Fatalf("add %v: %v", threshold, err)
+		}
+	}
+	entries := n.lt["amount"]
+
+	result := &MatchResult{}
+	n.Search(ctx, "amount", float64(20), result)
+
+	// "amount < threshold" is satisfied when threshold > value: only 30
+	// qualifies, neither 10 nor 20 do.
+	for _, e := range entries {
+		want := e.threshold > 20
+		if got := matched(result, e.part); got != want {
+			t.Fatalf("threshold %v: expected matched=%v, got %v", e.threshold, want, got)
+		}
+	}
+}
+
+func TestNumericRange_LessThanOrEqualBoundary(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+
+	for _, threshold := range []float64{10, 20, 30} {
+		if err := n.Add(ctx, numericPart("amount", operators.LessEquals, threshold)); err != nil {
+			t.Fatalf("add %v: %v", threshold, err)
+		}
+	}
+	entries := n.lte["amount"]
+
+	result := &MatchResult{}
+	n.Search(ctx, "amount", float64(20), result)
+
+	// "amount <= threshold" is satisfied when threshold >= value: 20 and 30
+	// qualify, 10 does not.
+	for _, e := range entries {
+		want := e.threshold >= 20
+		if got := matched(result, e.part); got != want {
+			t.Fatalf("threshold %v: expected matched=%v, got %v", e.threshold, want, got)
+		}
+	}
+}
+
+func TestNumericRange_MissingPath(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+	if err := n.Add(ctx, numericPart("event.data.amount", operators.Greater, float64(10))); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	result := &MatchResult{}
+	err := n.Match(ctx, map[string]any{"event": map[string]any{"data": map[string]any{}}}, result)
+	if err != nil {
+		t.Fatalf("expected a missing path to degrade gracefully, got error: %v", err)
+	}
+}
+
+func TestNumericRange_WrongTypedInput(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+	part := numericPart("event.data.amount", operators.Greater, float64(10))
+	if err := n.Add(ctx, part); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	result := &MatchResult{}
+	input := map[string]any{"event": map[string]any{"data": map[string]any{"amount": "not-a-number"}}}
+	if err := n.Match(ctx, input, result); err != nil {
+		t.Fatalf("match: %v", err)
+	}
+
+	if matched(result, n.gt["event.data.amount"][0].part) {
+		t.Fatalf("expected a wrong-typed input to never match")
+	}
+}
+
+func TestNumericRange_TimeLiteralExactNanoPrecision(t *testing.T) {
+	ctx := context.Background()
+	n := newNumericRangeMatcher(1).(*numericRange)
+
+	base := time.Unix(1_700_000_000, 0)
+	// Two timestamps 100ns apart. A float64 cast of unix nanos at this
+	// magnitude can't distinguish values this close (float64's exact-integer
+	// range is 2^53, far below ~1.7e18 nanoseconds), so a correct
+	// implementation must keep these as distinct int64 thresholds and
+	// compare them exactly.
+	earlier := base
+	later := base.Add(100 * time.Nanosecond)
+
+	if err := n.Add(ctx, numericPart("event.ts", operators.Greater, earlier)); err != nil {
+		t.Fatalf("add earlier: %v", err)
+	}
+	if err := n.Add(ctx, numericPart("event.ts", operators.Greater, later)); err != nil {
+		t.Fatalf("add later: %v", err)
+	}
+
+	entries := n.gtTime["event.ts"]
+	if len(entries) != 2 {
+		t.Fatalf("expected two distinct time thresholds, got %d", len(entries))
+	}
+	if entries[0].threshold == entries[1].threshold {
+		t.Fatalf("expected thresholds 100ns apart to remain distinct, got equal int64 values %d", entries[0].threshold)
+	}
+	if entries[0].threshold != earlier.UnixNano() || entries[1].threshold != later.UnixNano() {
+		t.Fatalf("expected exact unix-nano thresholds, got %v", entries)
+	}
+
+	// A value exactly between the two thresholds should satisfy "> earlier"
+	// but not "> later".
+	result := &MatchResult{}
+	mid := earlier.Add(50 * time.Nanosecond)
+	n.Search(ctx, "event.ts", mid, result)
+
+	if !matched(result, entries[0].part) {
+		t.Fatalf("expected the midpoint value to satisfy > earlier")
+	}
+	if matched(result, entries[1].part) {
+		t.Fatalf("expected the midpoint value to not satisfy > later")
+	}
+}
@@ -0,0 +1,287 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/common/overloads"
+	"github.com/ohler55/ojg/jp"
+)
+
+// EngineTypeStringAffix matches the startsWith, endsWith, and contains string
+// macros via prefix/suffix/substring tries rather than falling back to full
+// CEL evaluation.
+const EngineTypeStringAffix EngineType = 10
+
+func newStringAffixMatcher(concurrency int64) MatchingEngine {
+	return &stringAffixLookup{
+		lock:           &sync.RWMutex{},
+		vars:           map[string]struct{}{},
+		prefix:         map[string]*affixNode{},
+		suffix:         map[string]*affixNode{},
+		contains:       map[string]*affixNode{},
+		maxContainsLen: map[string]int{},
+		concurrency:    concurrency,
+	}
+}
+
+// affixNode is a single node within a trie keyed by byte, used for prefix,
+// suffix (via reversed input), and bounded substring matching.
+type affixNode struct {
+	children map[byte]*affixNode
+	parts    []*StoredExpressionPart
+}
+
+func (n *affixNode) child(b byte, create bool) *affixNode {
+	if n.children == nil {
+		if !create {
+			return nil
+		}
+		n.children = map[byte]*affixNode{}
+	}
+	if next, ok := n.children[b]; ok {
+		return next
+	}
+	if !create {
+		return nil
+	}
+	next := &affixNode{}
+	n.children[b] = next
+	return next
+}
+
+func (n *affixNode) insert(literal string, part *StoredExpressionPart) {
+	node := n
+	for i := 0; i < len(literal); i++ {
+		node = node.child(literal[i], true)
+	}
+	node.parts = append(node.parts, part)
+}
+
+func (n *affixNode) remove(literal string, part *StoredExpressionPart) bool {
+	node := n
+	for i := 0; i < len(literal); i++ {
+		node = node.child(literal[i], false)
+		if node == nil {
+			return false
+		}
+	}
+	for i, existing := range node.parts {
+		if part.EqualsStored(existing) {
+			node.parts = append(node.parts[:i], node.parts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// stringAffixLookup indexes startsWith, endsWith, and contains literals so
+// that matching a value against thousands of such predicates doesn't require
+// evaluating every expression's CEL program.
+//
+// Each of prefix, suffix, and contains is a trie per variable path, keyed by
+// the variable the predicate reads from.  This mirrors equalitySearch's
+// Ident filtering: a literal indexed for "a" must never surface as a match
+// for "b", even if the literal and the input value happen to collide, so
+// each variable gets its own trie rather than sharing one keyed by Ident at
+// read time.
+//
+// prefix and suffix are plain tries: walking the input (or its reverse) one
+// byte at a time and collecting every node's parts along the way yields every
+// stored literal that is a prefix (or suffix) of the input.
+//
+// contains is a simpler trie keyed the same way, but since a substring can
+// start anywhere in the input we walk it from every offset, bounded by the
+// longest contains literal we've indexed for that variable.  This is not as
+// fast as a true Aho-Corasick automaton with failure links, but it keeps
+// removal simple and is a large improvement over evaluating every stored
+// expression linearly.
+type stringAffixLookup struct {
+	lock *sync.RWMutex
+
+	vars map[string]struct{}
+
+	prefix   map[string]*affixNode
+	suffix   map[string]*affixNode
+	contains map[string]*affixNode
+
+	maxContainsLen map[string]int
+
+	concurrency int64
+}
+
+func (s *stringAffixLookup) Type() EngineType {
+	return EngineTypeStringAffix
+}
+
+func (s *stringAffixLookup) Add(ctx context.Context, p ExpressionPart) error {
+	literal := p.Predicate.LiteralAsString()
+	ident := p.Predicate.Ident
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.vars[ident] = struct{}{}
+	stored := p.ToStored()
+
+	switch p.Predicate.Operator {
+	case overloads.StartsWithString:
+		s.prefixTrie(ident).insert(literal, stored)
+	case overloads.EndsWithString:
+		s.suffixTrie(ident).insert(reverse(literal), stored)
+	case overloads.ContainsString:
+		s.containsTrie(ident).insert(literal, stored)
+		if len(literal) > s.maxContainsLen[ident] {
+			s.maxContainsLen[ident] = len(literal)
+		}
+	default:
+		return fmt.Errorf("stringAffixLookup only supports startsWith, endsWith, and contains")
+	}
+
+	return nil
+}
+
+func (s *stringAffixLookup) Remove(ctx context.Context, p ExpressionPart) error {
+	literal := p.Predicate.LiteralAsString()
+	ident := p.Predicate.Ident
+	stored := p.ToStored()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var ok bool
+	switch p.Predicate.Operator {
+	case overloads.StartsWithString:
+		if root, found := s.prefix[ident]; found {
+			ok = root.remove(literal, stored)
+		}
+	case overloads.EndsWithString:
+		if root, found := s.suffix[ident]; found {
+			ok = root.remove(reverse(literal), stored)
+		}
+	case overloads.ContainsString:
+		if root, found := s.contains[ident]; found {
+			ok = root.remove(literal, stored)
+		}
+	default:
+		return fmt.Errorf("stringAffixLookup only supports startsWith, endsWith, and contains")
+	}
+
+	if !ok {
+		return ErrExpressionPartNotFound
+	}
+	return nil
+}
+
+// prefixTrie, suffixTrie, and containsTrie return the per-variable trie root
+// for ident, creating it if necessary.  Callers must hold s.lock.
+func (s *stringAffixLookup) prefixTrie(ident string) *affixNode {
+	root, ok := s.prefix[ident]
+	if !ok {
+		root = &affixNode{}
+		s.prefix[ident] = root
+	}
+	return root
+}
+
+func (s *stringAffixLookup) suffixTrie(ident string) *affixNode {
+	root, ok := s.suffix[ident]
+	if !ok {
+		root = &affixNode{}
+		s.suffix[ident] = root
+	}
+	return root
+}
+
+func (s *stringAffixLookup) containsTrie(ident string) *affixNode {
+	root, ok := s.contains[ident]
+	if !ok {
+		root = &affixNode{}
+		s.contains[ident] = root
+	}
+	return root
+}
+
+func (s *stringAffixLookup) Match(ctx context.Context, input map[string]any, result *MatchResult) error {
+	pool := newErrPool(errPoolOpts{concurrency: s.concurrency})
+	for item := range s.vars {
+		path := item
+		pool.Go(func() error {
+			x, err := jp.ParseString(path)
+			if err != nil {
+				return err
+			}
+
+			str := ""
+			if res := x.Get(input); len(res) > 0 {
+				if value, ok := res[0].(string); ok {
+					str = value
+				}
+			}
+
+			s.Search(ctx, path, str, result)
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
+// Search returns all StoredExpressionParts whose startsWith/endsWith/contains
+// literal matches the given input, restricted to literals indexed for this
+// variable.
+func (s *stringAffixLookup) Search(ctx context.Context, variable string, input any, result *MatchResult) {
+	str, ok := input.(string)
+	if !ok {
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if root, ok := s.prefix[variable]; ok {
+		walk(root, str, result)
+	}
+	if root, ok := s.suffix[variable]; ok {
+		walk(root, reverse(str), result)
+	}
+	if root, ok := s.contains[variable]; ok {
+		maxLen := s.maxContainsLen[variable]
+		for start := 0; start < len(str); start++ {
+			end := start + maxLen
+			if end > len(str) {
+				end = len(str)
+			}
+			walk(root, str[start:end], result)
+		}
+	}
+}
+
+// walk traverses the trie along input, routing every StoredExpressionPart
+// found at each visited node through result.Add, since each such node
+// represents a literal that is a prefix of input.
+//
+// This must go through Add rather than AddExprs, the same as
+// equalitySearch: a part may be one leg of a multi-predicate, GroupID'd
+// expression, and Add is what lets the aggregator track that only one leg
+// has matched so far rather than reporting the whole expression as matched.
+func walk(root *affixNode, input string, result *MatchResult) {
+	node := root
+	for i := 0; i < len(input); i++ {
+		node = node.child(input[i], false)
+		if node == nil {
+			return
+		}
+		for _, part := range node.parts {
+			result.Add(part.EvaluableID, part.GroupID)
+		}
+	}
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
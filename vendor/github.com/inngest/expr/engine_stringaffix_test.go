@@ -0,0 +1,204 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+)
+
+func affixPart(ident, operator, literal string) ExpressionPart {
+	return ExpressionPart{
+		Predicate: Predicate{
+			Ident:    ident,
+			Operator: operator,
+			Literal:  literal,
+		},
+	}
+}
+
+func TestStringAffixLookup_PerVariableIsolation(t *testing.T) {
+	ctx := context.Background()
+	s := newStringAffixMatcher(1).(*stringAffixLookup)
+
+	// Two different variables indexing the same startsWith literal must not
+	// leak into each other's trie, otherwise a value for "a" that happens to
+	// match "b"'s literal would surface "b"'s expression part.
+	if err := s.Add(ctx, affixPart("a", overloads.StartsWithString, "foo")); err != nil {
+		t.Fatalf("add a: %v", err)
+	}
+	if err := s.Add(ctx, affixPart("b", overloads.StartsWithString, "foo")); err != nil {
+		t.Fatalf("add b: %v", err)
+	}
+
+	if s.prefix["a"] == s.prefix["b"] {
+		t.Fatalf("expected distinct tries for distinct variables, got the same trie")
+	}
+
+	aRoot, ok := s.prefix["a"]
+	if !ok {
+		t.Fatalf("expected a trie for variable %q", "a")
+	}
+	bRoot, ok := s.prefix["b"]
+	if !ok {
+		t.Fatalf("expected a trie for variable %q", "b")
+	}
+
+	aNode := walkTo(aRoot, "foo")
+	if aNode == nil || len(aNode.parts) != 1 {
+		t.Fatalf("expected exactly one part under a's trie, got %v", aNode)
+	}
+	bNode := walkTo(bRoot, "foo")
+	if bNode == nil || len(bNode.parts) != 1 {
+		t.Fatalf("expected exactly one part under b's trie, got %v", bNode)
+	}
+}
+
+func TestStringAffixLookup_AddUnsupportedOperator(t *testing.T) {
+	s := newStringAffixMatcher(1).(*stringAffixLookup)
+	err := s.Add(context.Background(), affixPart("a", operators.Equals, "foo"))
+	if err == nil {
+		t.Fatalf("expected an error for an unsupported operator")
+	}
+}
+
+func TestStringAffixLookup_RemoveMissing(t *testing.T) {
+	s := newStringAffixMatcher(1).(*stringAffixLookup)
+	err := s.Remove(context.Background(), affixPart("a", overloads.EndsWithString, "foo"))
+	if err != ErrExpressionPartNotFound {
+		t.Fatalf("expected ErrExpressionPartNotFound, got %v", err)
+	}
+}
+
+func TestStringAffixLookup_AddRemoveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newStringAffixMatcher(1).(*stringAffixLookup)
+
+	part := affixPart("a", overloads.ContainsString, "acme")
+	if err := s.Add(ctx, part); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	node := walkTo(s.contains["a"], "acme")
+	if node == nil || len(node.parts) != 1 {
+		t.Fatalf("expected one part after add, got %v", node)
+	}
+
+	if err := s.Remove(ctx, part); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	node = walkTo(s.contains["a"], "acme")
+	if node == nil || len(node.parts) != 0 {
+		t.Fatalf("expected no parts after remove, got %v", node)
+	}
+}
+
+// matched reports whether Add has routed part (identified by its own
+// EvaluableID/GroupID) into result, using the same GroupMatches accessor
+// inequalitySearch uses to gate grouped predicates.
+func matched(result *MatchResult, part *StoredExpressionPart) bool {
+	return int8(result.GroupMatches(part.EvaluableID, part.GroupID)) > 0
+}
+
+func TestStringAffixLookup_Match(t *testing.T) {
+	ctx := context.Background()
+	s := newStringAffixMatcher(1).(*stringAffixLookup)
+
+	parts := []ExpressionPart{
+		affixPart("event.data.email", overloads.EndsWithString, "@acme.com"),
+		affixPart("event.data.id", overloads.StartsWithString, "prod-"),
+		affixPart("event.data.message", overloads.ContainsString, "urgent"),
+		// No variable's value matches this one, so it must never show up as
+		// matched.
+		affixPart("event.data.id", overloads.StartsWithString, "staging-"),
+	}
+	for _, p := range parts {
+		if err := s.Add(ctx, p); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	input := map[string]any{
+		"event": map[string]any{
+			"data": map[string]any{
+				"email":   "user@acme.com",
+				"id":      "prod-123",
+				"message": "this is urgent, please read",
+			},
+		},
+	}
+
+	result := &MatchResult{}
+	if err := s.Match(ctx, input, result); err != nil {
+		t.Fatalf("match: %v", err)
+	}
+
+	emailPart := walkTo(s.suffix["event.data.email"], reverse("@acme.com")).parts[0]
+	idPart := walkTo(s.prefix["event.data.id"], "prod-").parts[0]
+	messagePart := walkTo(s.contains["event.data.message"], "urgent").parts[0]
+	stagingPart := walkTo(s.prefix["event.data.id"], "staging-").parts[0]
+
+	for _, tc := range []struct {
+		name string
+		part *StoredExpressionPart
+		want bool
+	}{
+		{"endsWith @acme.com", emailPart, true},
+		{"startsWith prod-", idPart, true},
+		{"contains urgent", messagePart, true},
+		{"startsWith staging- (no match)", stagingPart, false},
+	} {
+		if got := matched(result, tc.part); got != tc.want {
+			t.Fatalf("%s: expected matched=%v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestStringAffixLookup_Match_SameLiteralDifferentVariable(t *testing.T) {
+	ctx := context.Background()
+	s := newStringAffixMatcher(1).(*stringAffixLookup)
+
+	a := affixPart("a", overloads.StartsWithString, "foo")
+	b := affixPart("b", overloads.StartsWithString, "foo")
+	if err := s.Add(ctx, a); err != nil {
+		t.Fatalf("add a: %v", err)
+	}
+	if err := s.Add(ctx, b); err != nil {
+		t.Fatalf("add b: %v", err)
+	}
+
+	// "a" has a value starting with "foo"; "b" does not. Without per-variable
+	// isolation, b's part would incorrectly surface as matched too, since
+	// both index the same literal.
+	input := map[string]any{"a": "foo-bar", "b": "bar-baz"}
+
+	result := &MatchResult{}
+	if err := s.Match(ctx, input, result); err != nil {
+		t.Fatalf("match: %v", err)
+	}
+
+	aPart := walkTo(s.prefix["a"], "foo").parts[0]
+	bPart := walkTo(s.prefix["b"], "foo").parts[0]
+
+	if !matched(result, aPart) {
+		t.Fatalf("expected a's startsWith(\"foo\") to match")
+	}
+	if matched(result, bPart) {
+		t.Fatalf("expected b's startsWith(\"foo\") to not match, since b's value doesn't start with foo")
+	}
+}
+
+// walkTo traverses the trie rooted at root along literal and returns the
+// node reached, or nil if literal isn't fully present.
+func walkTo(root *affixNode, literal string) *affixNode {
+	node := root
+	for i := 0; i < len(literal); i++ {
+		node = node.child(literal[i], false)
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}